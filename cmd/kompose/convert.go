@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+	"github.com/kubernetes-incubator/kompose/pkg/loader/compose"
+)
+
+var (
+	convertFiles       []string
+	convertOutput      string
+	convertStdout      bool
+	convertJSON        bool
+	convertProfiles    []string
+	convertProjectName string
+	convertValidate    bool
+	convertBuild       string
+	convertPush        bool
+)
+
+// ConvertCmd loads the Compose files and, with --build, builds their images.
+// It does not run a transformer, so it writes no manifests, and --out,
+// --stdout and --json have no effect.
+var ConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert a Docker Compose file to Kubernetes manifests",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConvert()
+	},
+}
+
+func init() {
+	ConvertCmd.Flags().StringSliceVarP(&convertFiles, "file", "f", nil, "Specify an alternate compose file (default: docker-compose.yml), can be used multiple times")
+	ConvertCmd.Flags().StringVarP(&convertOutput, "out", "o", "", "Write the generated manifests to a file instead of stdout")
+	ConvertCmd.Flags().BoolVar(&convertStdout, "stdout", true, "Print the generated manifests to stdout")
+	ConvertCmd.Flags().BoolVarP(&convertJSON, "json", "j", false, "Generate JSON manifests instead of YAML")
+	ConvertCmd.Flags().StringSliceVar(&convertProfiles, "profile", nil, "Enable a Compose profile, can be used multiple times")
+	ConvertCmd.Flags().StringVarP(&convertProjectName, "project-name", "p", "", "Override the Compose project name (default: COMPOSE_PROJECT_NAME, then the working directory's name)")
+	ConvertCmd.Flags().BoolVar(&convertValidate, "validate", true, "Validate the compose file(s) against the Compose Specification before converting")
+	ConvertCmd.Flags().StringVar(&convertBuild, "build", "", "Build images for services with a build: block before converting, one of local|buildkit")
+	ConvertCmd.Flags().BoolVar(&convertPush, "push", false, "Push built images to their registry (requires --build)")
+}
+
+func runConvert() error {
+	opt := kobject.ConvertOptions{
+		ToStdout:     convertStdout,
+		GenerateJSON: convertJSON,
+		Profiles:     convertProfiles,
+		ProjectName:  convertProjectName,
+		Validate:     convertValidate,
+		BuildMode:    convertBuild,
+		Push:         convertPush,
+	}
+
+	_, err := compose.Load(convertFiles, opt)
+	return err
+}
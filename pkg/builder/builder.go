@@ -0,0 +1,58 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder builds the images a Compose `build:` block describes
+// before kompose hands the resulting tag off to a transformer. It supports
+// two interchangeable strategies: shelling out to `docker build`, or driving
+// BuildKit natively, so users without a Docker daemon on PATH can still build.
+package builder
+
+import (
+	"context"
+	"io"
+)
+
+// Options describes a single image build, gathered from a service's
+// `build:` block by the compose loader.
+type Options struct {
+	// ContextDir is `build.context`, the directory (or git URL) to build from.
+	// DockerBuilder accepts either; BuildKitBuilder syncs it as a local
+	// directory, so a git URL does not work there.
+	ContextDir string
+	// Dockerfile is `build.dockerfile`, relative to ContextDir. Defaults to
+	// "Dockerfile" if empty.
+	Dockerfile string
+	// Tag is the image reference to build and, if Push is set, push
+	Tag string
+	// BuildArgs is `build.args`
+	BuildArgs map[string]*string
+	// Target is `build.target`, the Dockerfile stage to stop at
+	Target string
+	// CacheFrom is `build.cache_from`
+	CacheFrom []string
+	// Push pushes Tag to its registry once the build succeeds
+	Push bool
+	// Progress receives the builder's human-readable build log. A nil
+	// Progress discards the output.
+	Progress io.Writer
+}
+
+// Builder builds a single image from a Compose `build:` block. Build logs
+// are streamed to opt.Progress as they happen; the returned string is the
+// image reference that was built (and, if opt.Push was set, pushed).
+type Builder interface {
+	Build(ctx context.Context, opt Options) (string, error)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"io/ioutil"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/progress/progressui"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// BuildKitBuilder drives a BuildKit daemon natively over its gRPC API,
+// bypassing the `docker` CLI entirely. Useful in environments that run a
+// standalone `buildkitd` (ex. CI, rootless builds) without a full Docker
+// installation.
+type BuildKitBuilder struct {
+	// Address is the BuildKit daemon's listen address, ex.
+	// "unix:///run/buildkit/buildkitd.sock" or "tcp://127.0.0.1:1234"
+	Address string
+}
+
+// NewBuildKitBuilder returns a Builder that talks to the BuildKit daemon at address.
+func NewBuildKitBuilder(address string) *BuildKitBuilder {
+	return &BuildKitBuilder{Address: address}
+}
+
+// Build implements Builder.
+func (b *BuildKitBuilder) Build(ctx context.Context, opt Options) (string, error) {
+	c, err := client.New(ctx, b.Address)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to connect to buildkitd")
+	}
+	defer c.Close()
+
+	solveOpt := buildkitSolveOpt(opt)
+
+	ch := make(chan *client.SolveStatus)
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		_, err := c.Solve(ctx, nil, solveOpt, ch)
+		return err
+	})
+	eg.Go(func() error {
+		// Stream solve status to opt.Progress, the same writer
+		// DockerBuilder.Build pipes `docker build`'s own output to.
+		// A nil opt.Progress discards the output.
+		progress := opt.Progress
+		if progress == nil {
+			progress = ioutil.Discard
+		}
+		_, err := progressui.DisplaySolveStatus(ctx, "", nil, progress, ch)
+		return err
+	})
+
+	if err := eg.Wait(); err != nil {
+		return "", errors.Wrapf(err, "buildkit solve failed for %q", opt.Tag)
+	}
+
+	return opt.Tag, nil
+}
+
+// buildkitSolveOpt translates Options into the client.SolveOpt BuildKit's
+// gRPC API expects, factored out of Build so it can be tested without a
+// live buildkitd to connect to.
+func buildkitSolveOpt(opt Options) client.SolveOpt {
+	dockerfile := opt.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	frontendAttrs := map[string]string{
+		"filename": dockerfile,
+	}
+	if opt.Target != "" {
+		frontendAttrs["target"] = opt.Target
+	}
+	for name, value := range opt.BuildArgs {
+		if value == nil {
+			continue
+		}
+		frontendAttrs["build-arg:"+name] = *value
+	}
+
+	exporterAttrs := map[string]string{
+		"name": opt.Tag,
+	}
+	if opt.Push {
+		exporterAttrs["push"] = "true"
+	}
+
+	var cacheImports []client.CacheOptionsEntry
+	for _, cacheFrom := range opt.CacheFrom {
+		cacheImports = append(cacheImports, client.CacheOptionsEntry{
+			Type:  "registry",
+			Attrs: map[string]string{"ref": cacheFrom},
+		})
+	}
+
+	return client.SolveOpt{
+		Exports: []client.ExportEntry{{
+			Type:  client.ExporterImage,
+			Attrs: exporterAttrs,
+		}},
+		LocalDirs: map[string]string{
+			"context":    opt.ContextDir,
+			"dockerfile": opt.ContextDir,
+		},
+		Frontend:      "dockerfile.v0",
+		FrontendAttrs: frontendAttrs,
+		CacheImports:  cacheImports,
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+)
+
+func TestBuildkitSolveOpt(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Options
+		want client.SolveOpt
+	}{
+		{
+			name: "tag and context only, dockerfile defaults",
+			opt: Options{
+				Tag:        "web:latest",
+				ContextDir: ".",
+			},
+			want: client.SolveOpt{
+				Exports: []client.ExportEntry{{
+					Type:  client.ExporterImage,
+					Attrs: map[string]string{"name": "web:latest"},
+				}},
+				LocalDirs: map[string]string{
+					"context":    ".",
+					"dockerfile": ".",
+				},
+				Frontend:      "dockerfile.v0",
+				FrontendAttrs: map[string]string{"filename": "Dockerfile"},
+			},
+		},
+		{
+			name: "dockerfile, target, push and cache-from",
+			opt: Options{
+				Tag:        "web:latest",
+				ContextDir: ".",
+				Dockerfile: "Dockerfile.prod",
+				Target:     "release",
+				CacheFrom:  []string{"web:cache"},
+				Push:       true,
+			},
+			want: client.SolveOpt{
+				Exports: []client.ExportEntry{{
+					Type: client.ExporterImage,
+					Attrs: map[string]string{
+						"name": "web:latest",
+						"push": "true",
+					},
+				}},
+				LocalDirs: map[string]string{
+					"context":    ".",
+					"dockerfile": ".",
+				},
+				Frontend: "dockerfile.v0",
+				FrontendAttrs: map[string]string{
+					"filename": "Dockerfile.prod",
+					"target":   "release",
+				},
+				CacheImports: []client.CacheOptionsEntry{{
+					Type:  "registry",
+					Attrs: map[string]string{"ref": "web:cache"},
+				}},
+			},
+		},
+		{
+			name: "build-args, nil values skipped",
+			opt: Options{
+				Tag:        "web:latest",
+				ContextDir: ".",
+				BuildArgs: map[string]*string{
+					"VERSION": strPtr("1.2.3"),
+					"SKIPPED": nil,
+				},
+			},
+			want: client.SolveOpt{
+				Exports: []client.ExportEntry{{
+					Type:  client.ExporterImage,
+					Attrs: map[string]string{"name": "web:latest"},
+				}},
+				LocalDirs: map[string]string{
+					"context":    ".",
+					"dockerfile": ".",
+				},
+				Frontend: "dockerfile.v0",
+				FrontendAttrs: map[string]string{
+					"filename":          "Dockerfile",
+					"build-arg:VERSION": "1.2.3",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildkitSolveOpt(tt.opt)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildkitSolveOpt(%+v) =\n  %+v\nwant\n  %+v", tt.opt, got, tt.want)
+			}
+		})
+	}
+}
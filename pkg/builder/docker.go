@@ -0,0 +1,90 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// DockerBuilder shells out to the `docker` CLI already on PATH, the same way
+// `docker-compose build` does. It requires neither a BuildKit daemon nor the
+// `moby/buildkit` client library, making it the default for users who just
+// want their existing Docker install to keep working.
+type DockerBuilder struct{}
+
+// NewDockerBuilder returns a Builder that shells out to `docker build`.
+func NewDockerBuilder() *DockerBuilder {
+	return &DockerBuilder{}
+}
+
+// Build implements Builder.
+func (b *DockerBuilder) Build(ctx context.Context, opt Options) (string, error) {
+	args := dockerBuildArgs(opt)
+
+	if err := run(ctx, opt.Progress, "docker", args...); err != nil {
+		return "", errors.Wrapf(err, "docker build failed for %q", opt.Tag)
+	}
+
+	if opt.Push {
+		if err := run(ctx, opt.Progress, "docker", "push", opt.Tag); err != nil {
+			return "", errors.Wrapf(err, "docker push failed for %q", opt.Tag)
+		}
+	}
+
+	return opt.Tag, nil
+}
+
+// dockerBuildArgs builds the `docker build` argument list for opt, factored
+// out of Build so the flag construction can be tested without shelling out.
+func dockerBuildArgs(opt Options) []string {
+	args := []string{"build", "-t", opt.Tag}
+
+	dockerfile := opt.Dockerfile
+	if dockerfile != "" {
+		args = append(args, "-f", dockerfile)
+	}
+
+	if opt.Target != "" {
+		args = append(args, "--target", opt.Target)
+	}
+
+	for _, cacheFrom := range opt.CacheFrom {
+		args = append(args, "--cache-from", cacheFrom)
+	}
+
+	for name, value := range opt.BuildArgs {
+		if value == nil {
+			continue
+		}
+		args = append(args, "--build-arg", name+"="+*value)
+	}
+
+	return append(args, opt.ContextDir)
+}
+
+func run(ctx context.Context, progress io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if progress != nil {
+		cmd.Stdout = progress
+		cmd.Stderr = progress
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestDockerBuildArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Options
+		want []string
+	}{
+		{
+			name: "tag and context only",
+			opt: Options{
+				Tag:        "web:latest",
+				ContextDir: ".",
+			},
+			want: []string{"build", "-t", "web:latest", "."},
+		},
+		{
+			name: "dockerfile, target and cache-from",
+			opt: Options{
+				Tag:        "web:latest",
+				ContextDir: ".",
+				Dockerfile: "Dockerfile.prod",
+				Target:     "release",
+				CacheFrom:  []string{"web:cache"},
+			},
+			want: []string{"build", "-t", "web:latest", "-f", "Dockerfile.prod", "--target", "release", "--cache-from", "web:cache", "."},
+		},
+		{
+			name: "build-args, nil values skipped",
+			opt: Options{
+				Tag:        "web:latest",
+				ContextDir: ".",
+				BuildArgs: map[string]*string{
+					"VERSION": strPtr("1.2.3"),
+					"SKIPPED": nil,
+				},
+			},
+			want: []string{"build", "-t", "web:latest", "--build-arg", "VERSION=1.2.3", "."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dockerBuildArgs(tt.opt)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dockerBuildArgs(%+v) = %v, want %v", tt.opt, got, tt.want)
+			}
+		})
+	}
+}
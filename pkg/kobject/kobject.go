@@ -0,0 +1,218 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kobject holds the intermediate representation kompose builds from
+// the various Compose loaders (v1, v2, v3) before handing it off to a
+// transformer. It is loader-agnostic: the only Compose library type it uses
+// is libcompose's yaml.MemStringorInt, for memory sizes.
+package kobject
+
+import (
+	libcomposeyaml "github.com/docker/libcompose/yaml"
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// ConvertOptions holds the command-line flags that influence how a loader
+// parses the input files, independently of the Compose syntax itself.
+type ConvertOptions struct {
+	// ToStdout controls whether output should be written to stdout
+	ToStdout bool
+	// GenerateJSON, when set, causes the transformer to emit JSON instead of YAML
+	GenerateJSON bool
+	// Profiles is the set of Compose `--profile` values to activate; services
+	// that are gated behind a profile not in this list are skipped
+	Profiles []string
+	// ProjectName overrides the Compose project name compose-go would
+	// otherwise derive from COMPOSE_PROJECT_NAME or the working directory;
+	// it's used to namespace generated resource names when building images.
+	// Empty means "let compose-go pick its own default"
+	ProjectName string
+	// Validate, when set, causes the loader to check the project compose-go
+	// loaded from the Compose files against the CUE schema in
+	// pkg/loader/compose/validate before mapping it to a KomposeObject
+	Validate bool
+	// BuildMode selects how services with a `build:` block get their image
+	// built: "" to skip building, "local" to shell out to
+	// `docker build`, or "buildkit" to drive BuildKit natively
+	BuildMode string
+	// Push pushes each built image to its registry once the build succeeds
+	Push bool
+}
+
+// KomposeObject holds the generic struct that is parsed from any Compose
+// version (1, 2 or 3). It is the input the transformers consume.
+type KomposeObject struct {
+	ServiceConfigs map[string]ServiceConfig
+
+	// ConfigMaps holds the top-level `configs:` section, keyed by name
+	ConfigMaps map[string]ConfigMap
+	// Secrets holds the top-level `secrets:` section, keyed by name
+	Secrets map[string]Secret
+
+	// LoadedFrom records where this KomposeObject was parsed from,
+	// ex. "compose", "bundle"
+	LoadedFrom string
+}
+
+// ConfigMap is a single entry of the top-level Compose `configs:` section.
+type ConfigMap struct {
+	Name string
+	// File is the path the config's content was read from; unset for
+	// External configs
+	File string
+	// Content is the file's contents, read eagerly at load time; empty for
+	// External configs
+	Content string
+	// External marks a config that is assumed to already exist in the
+	// cluster, so kompose shouldn't generate a ConfigMap for it
+	External bool
+}
+
+// Secret is a single entry of the top-level Compose `secrets:` section.
+type Secret struct {
+	Name string
+	// File is the path the secret's content was read from; unset for
+	// External secrets
+	File string
+	// Content is the file's raw bytes, read eagerly at load time; empty
+	// for External secrets. Kept as []byte rather than string since secret
+	// files (TLS keys/certs, keystores) are routinely binary, and
+	// Kubernetes' own Secret.Data is base64-safe for exactly that reason
+	Content []byte
+	// External marks a secret that is assumed to already exist in the
+	// cluster, so kompose shouldn't generate a Secret for it
+	External bool
+}
+
+// ServiceConfig holds the basic struct of a Service, which is defined by
+// a Compose file
+type ServiceConfig struct {
+	ContainerName string
+	Image         string
+	Environment   []EnvVar
+	EnvFile       []string
+	Port          []Ports
+	Command       []string
+	WorkingDir    string
+	Args          []string
+	Volumes       []string
+	Network       []string
+	Labels        map[string]string
+	Annotations   map[string]string
+	CapAdd        []string
+	CapDrop       []string
+	Expose        []string
+	Privileged    bool
+	Restart       string
+	User          string
+	Stdin         bool
+	Tty           bool
+	MemLimit      libcomposeyaml.MemStringorInt
+	TmpFs         []string
+
+	ServiceType   string
+	ExposeService string
+
+	// ImagePullSecrets names the Kubernetes Secrets that should be set on the
+	// generated PodSpec's ImagePullSecrets, populated from `x-pull-secret` /
+	// the `kompose.image-pull-secret` label.
+	ImagePullSecrets []string
+	// ImagePullPolicy overrides the default pull policy kompose derives from
+	// the image tag, populated from `x-pull-policy` / the
+	// `kompose.image-pull-policy` label.
+	ImagePullPolicy api.PullPolicy
+
+	// Configs lists this service's per-service `configs:` mounts, each
+	// referencing an entry in KomposeObject.ConfigMaps by name
+	Configs []FileReferenceConfig
+	// Secrets lists this service's per-service `secrets:` mounts, each
+	// referencing an entry in KomposeObject.Secrets by name
+	Secrets []FileReferenceConfig
+
+	// Replicas is the Deployment replica count, from `deploy.replicas`.
+	// A nil pointer means "not set", so the transformer keeps its own
+	// default of 1; a non-nil 0 is an explicit `replicas: 0` and is honored
+	// as-is, distinct from the zero value meaning "unset".
+	Replicas *int
+
+	// CPULimit is `deploy.resources.limits.cpus`, in Kubernetes CPU quantity
+	// notation (ex. "0.5")
+	CPULimit string
+	// CPUReservation is `deploy.resources.reservations.cpus`, used as the
+	// container's CPU request
+	CPUReservation string
+	// MemReservation is `deploy.resources.reservations.memory`, used as the
+	// container's memory request
+	MemReservation libcomposeyaml.MemStringorInt
+
+	// RollingUpdateMaxSurge is `deploy.update_config.parallelism`, used as
+	// the Deployment strategy's RollingUpdate.MaxSurge when `order` is
+	// "start-first" (the Kubernetes-native ordering)
+	RollingUpdateMaxSurge int
+	// RollingUpdateMaxUnavailable is `deploy.update_config.parallelism`, used
+	// as the Deployment strategy's RollingUpdate.MaxUnavailable when `order`
+	// is "stop-first"
+	RollingUpdateMaxUnavailable int
+
+	// RestartPolicy is `deploy.restart_policy.condition`, translated to the
+	// Kubernetes PodSpec.RestartPolicy vocabulary (Always/OnFailure/Never)
+	RestartPolicy api.RestartPolicy
+
+	// Placement holds the `deploy.placement.constraints` entries that take
+	// the Swarm `<label> == <value>` form, translated to a Kubernetes
+	// NodeSelector (ex. "node.labels.foo == bar" -> {"foo": "bar"})
+	Placement map[string]string
+
+	// Build is `build.context`, the directory (or git URL) to build from.
+	// Empty unless the service has a `build:` block.
+	Build string
+	// Dockerfile is `build.dockerfile`, relative to Build
+	Dockerfile string
+	// BuildArgs is `build.args`
+	BuildArgs map[string]*string
+	// BuildTarget is `build.target`, the Dockerfile stage to stop at
+	BuildTarget string
+	// CacheFrom is `build.cache_from`
+	CacheFrom []string
+}
+
+// FileReferenceConfig is a single entry of a service's `configs:` or
+// `secrets:` mount list, resolved to the target path and ownership kompose
+// will project it to inside the container.
+type FileReferenceConfig struct {
+	// Source is the name of the top-level config/secret being mounted
+	Source string
+	// Target is the absolute in-container path to mount it at
+	Target string
+	UID    string
+	GID    string
+	// Mode is the file's permission bits, or nil to take Kubernetes' default
+	Mode *uint32
+}
+
+// EnvVar holds the environment variable struct of a container
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// Ports holds the port struct of a container
+type Ports struct {
+	HostPort      int32
+	ContainerPort int32
+	HostIP        string
+	Protocol      api.Protocol
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import "github.com/kubernetes-incubator/kompose/pkg/kobject"
+
+// Load parses the given Compose files into a kobject.KomposeObject. It is
+// the only symbol cmd/kompose needs from this package; parseV3 and its
+// helpers stay unexported since nothing outside this package should depend
+// on Compose-version-specific parsing details.
+func Load(files []string, opt kobject.ConvertOptions) (kobject.KomposeObject, error) {
+	return parseV3(files, opt)
+}
@@ -17,86 +17,165 @@ limitations under the License.
 package compose
 
 import (
-	libcomposeyaml "github.com/docker/libcompose/yaml"
+	"context"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 
+	libcomposeyaml "github.com/docker/libcompose/yaml"
+
 	"k8s.io/kubernetes/pkg/api"
 
-	"github.com/docker/cli/cli/compose/loader"
-	"github.com/docker/cli/cli/compose/types"
+	composetypes "github.com/compose-spec/compose-go/types"
+
+	"github.com/compose-spec/compose-go/cli"
+	composeloader "github.com/compose-spec/compose-go/loader"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/kubernetes-incubator/kompose/pkg/builder"
 	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+	"github.com/kubernetes-incubator/kompose/pkg/loader/compose/validate"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 )
 
 // The purpose of this is not to deploy, but to be able to parse
 // v3 of Docker Compose into a suitable format. In this case, whatever is returned
-// by docker/cli's ServiceConfig
-func parseV3(files []string) (kobject.KomposeObject, error) {
+// by compose-go's Project
+func parseV3(files []string, opt kobject.ConvertOptions) (kobject.KomposeObject, error) {
+
+	// compose-go handles multi-file merging, interpolation (OS env + .env)
+	// and profile filtering for us, so we no longer have to hand-roll any
+	// of that against files[0] alone.
+	projectOpts := []cli.ProjectOptionsFn{
+		cli.WithOsEnv,
+		cli.WithDotEnv,
+		cli.WithProfiles(opt.Profiles),
+	}
+	if opt.ProjectName != "" {
+		// --project-name overrides compose-go's own default (COMPOSE_PROJECT_NAME,
+		// then the working directory's basename)
+		projectOpts = append(projectOpts, cli.WithName(opt.ProjectName))
+	}
 
-	// In order to get V3 parsing to work, we have to go through some preliminary steps
-	// for us to hack up github.com/docker/cli in order to correctly convert to a kobject.KomposeObject
+	options, err := cli.NewProjectOptions(files, projectOpts...)
+	if err != nil {
+		return kobject.KomposeObject{}, errors.Wrap(err, "cli.NewProjectOptions failed")
+	}
 
-	// Gather the working directory
-	workingDir, err := getComposeFileDir(files)
+	project, err := cli.ProjectFromOptions(options)
 	if err != nil {
-		return kobject.KomposeObject{}, err
+		return kobject.KomposeObject{}, errors.Wrap(err, "cli.ProjectFromOptions failed")
 	}
 
-	// Load and then parse the YAML first!
-	loadedFile, err := ioutil.ReadFile(files[0])
+	// Validate against the resolved project, not the raw files: compose-go
+	// has already merged multiple files, applied defaults and substituted
+	// `${VAR}` interpolation by this point, so a typed field (ex.
+	// deploy.replicas) is a concrete int rather than the literal string
+	// "${REPLICAS}" the raw YAML would still contain.
+	if opt.Validate {
+		if err := validateProject(project); err != nil {
+			return kobject.KomposeObject{}, err
+		}
+	}
+
+	// TODO: Check all "unsupported" keys and output details
+	// Specifically, keys such as "volumes_from" are not supported in V3.
+
+	// Finally, we convert the object from compose-go's Project to our appropriate one
+	komposeObject, err := dockerComposeToKomposeMapping(project)
 	if err != nil {
 		return kobject.KomposeObject{}, err
 	}
 
-	// Parse the Compose File
-	parsedComposeFile, err := loader.ParseYAML(loadedFile)
-	if err != nil {
+	if err := buildServices(komposeObject, project.Name, opt); err != nil {
 		return kobject.KomposeObject{}, err
 	}
 
-	// Config file
-	configFile := types.ConfigFile{
-		Filename: files[0],
-		Config:   parsedComposeFile,
+	return komposeObject, nil
+}
+
+// buildServices builds, and optionally pushes, the image for every service
+// with a `build:` block, tagging it with the service's `image:` if set or
+// "<project>_<service>:latest" otherwise, then rewrites serviceConfig.Image
+// to the tag that was built so the transformer doesn't need to know building
+// ever happened.
+func buildServices(komposeObject kobject.KomposeObject, projectName string, opt kobject.ConvertOptions) error {
+	if opt.BuildMode == "" {
+		return nil
 	}
 
-	// Config details
-	// Environment is nil as docker/cli loads the appropriate environmental values itself
-	configDetails := types.ConfigDetails{
-		WorkingDir:  workingDir,
-		ConfigFiles: []types.ConfigFile{configFile},
-		Environment: nil,
+	var b builder.Builder
+	switch opt.BuildMode {
+	case "local":
+		b = builder.NewDockerBuilder()
+	case "buildkit":
+		b = builder.NewBuildKitBuilder(os.Getenv("BUILDKIT_HOST"))
+	default:
+		return fmt.Errorf("unsupported --build mode %q, must be one of local|buildkit", opt.BuildMode)
 	}
 
-	// Actual config
-	// We load it in order to retrieve the parsed output configuration!
-	// This will output a github.com/docker/cli ServiceConfig
-	// Which is similar to our version of ServiceConfig
-	config, err := loader.Load(configDetails)
-	if err != nil {
-		return kobject.KomposeObject{}, err
+	for name, serviceConfig := range komposeObject.ServiceConfigs {
+		if serviceConfig.Build == "" {
+			continue
+		}
+
+		tag := serviceConfig.Image
+		if tag == "" {
+			tag = fmt.Sprintf("%s_%s:latest", projectName, name)
+		}
+
+		builtTag, err := b.Build(context.Background(), builder.Options{
+			ContextDir: serviceConfig.Build,
+			Dockerfile: serviceConfig.Dockerfile,
+			Tag:        tag,
+			BuildArgs:  serviceConfig.BuildArgs,
+			Target:     serviceConfig.BuildTarget,
+			CacheFrom:  serviceConfig.CacheFrom,
+			Push:       opt.Push,
+			Progress:   log.StandardLogger().Writer(),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "building service %q", name)
+		}
+
+		serviceConfig.Image = builtTag
+		komposeObject.ServiceConfigs[name] = serviceConfig
 	}
 
-	// TODO: Check all "unsupported" keys and output details
-	// Specifically, keys such as "volumes_from" are not supported in V3.
+	return nil
+}
 
-	// Finally, we convert the object from docker/cli's ServiceConfig to our appropriate one
-	komposeObject, err := dockerComposeToKomposeMapping(config)
+// validateProject runs the CUE schema in pkg/loader/compose/validate
+// against project and reports every schema violation it has at once. It
+// runs only after compose-go has loaded the files, so an error compose-go
+// itself hits while loading is returned on its own, before any schema check.
+// project is re-marshaled to YAML and re-parsed into the generic
+// map[string]interface{} validate.Validate expects, rather than reading the
+// input files directly, because project.MarshalYAML (compose-go's own
+// "docker compose config" renderer) already has interpolation and defaults
+// resolved -- the raw files would still contain un-substituted "${VAR}"
+// strings in fields the schema requires to be concrete, ex. deploy.replicas.
+func validateProject(project *composetypes.Project) error {
+	resolved, err := yaml.Marshal(project)
 	if err != nil {
-		return kobject.KomposeObject{}, err
+		return errors.Wrap(err, "marshalling resolved compose project for validation")
 	}
 
-	return komposeObject, nil
+	parsed, err := composeloader.ParseYAML(resolved)
+	if err != nil {
+		return errors.Wrap(err, "re-parsing resolved compose project for validation")
+	}
+
+	return validate.Validate(parsed)
 }
 
 // Convert the Docker Compose v3 volumes to []string (the old way)
 // TODO: Check to see if it's a "bind" or "volume". Ignore for now.
 // TODO: Refactor it similar to loadV3Ports
 // See: https://docs.docker.com/compose/compose-file/#long-syntax-2
-func loadV3Volumes(volumes []types.ServiceVolumeConfig) []string {
+func loadV3Volumes(volumes []composetypes.ServiceVolumeConfig) []string {
 	var volArray []string
 	for _, vol := range volumes {
 
@@ -117,7 +196,7 @@ func loadV3Volumes(volumes []types.ServiceVolumeConfig) []string {
 }
 
 // Convert Docker Compose v3 ports to kobject.Ports
-func loadV3Ports(ports []types.ServicePortConfig) []kobject.Ports {
+func loadV3Ports(ports []composetypes.ServicePortConfig) []kobject.Ports {
 	komposePorts := []kobject.Ports{}
 
 	for _, port := range ports {
@@ -137,7 +216,139 @@ func loadV3Ports(ports []types.ServicePortConfig) []kobject.Ports {
 	return komposePorts
 }
 
-func dockerComposeToKomposeMapping(composeObject *types.Config) (kobject.KomposeObject, error) {
+// handlePullPolicy converts the user-supplied x-pull-policy / kompose.image-pull-policy
+// value into a Kubernetes api.PullPolicy, rejecting anything Kubernetes wouldn't recognize.
+func handlePullPolicy(value string) (api.PullPolicy, error) {
+	switch api.PullPolicy(value) {
+	case api.PullAlways, api.PullIfNotPresent, api.PullNever:
+		return api.PullPolicy(value), nil
+	default:
+		return "", fmt.Errorf("unsupported image pull policy %q, must be one of Always|IfNotPresent|Never", value)
+	}
+}
+
+// defaultImagePullPolicy mirrors the Kubernetes default: ":latest" (or an
+// untagged) image is always pulled, anything else is pulled only if it's
+// missing from the node.
+func defaultImagePullPolicy(image string) api.PullPolicy {
+	if tag := imageTag(image); tag == "" || tag == "latest" {
+		return api.PullAlways
+	}
+	return api.PullIfNotPresent
+}
+
+// imageTag returns the tag portion of a Docker image reference, or "" if
+// none was given (in which case Docker itself defaults to "latest").
+func imageTag(image string) string {
+	// Strip off the digest, if any, first -- "repo@sha256:..." has no tag.
+	if strings.Contains(image, "@") {
+		return ""
+	}
+
+	lastColon := strings.LastIndex(image, ":")
+	if lastColon == -1 {
+		return ""
+	}
+
+	// Guard against the ":" in "host:port/repo" being mistaken for a tag.
+	if strings.Contains(image[lastColon:], "/") {
+		return ""
+	}
+
+	return image[lastColon+1:]
+}
+
+// loadV3Configs reads the top-level `configs:` section into kobject.ConfigMap,
+// eagerly reading the backing file from disk. External configs are kept as a
+// reference only -- there's nothing on disk to read, the ConfigMap is assumed
+// to already exist in the cluster.
+func loadV3Configs(configs map[string]composetypes.ConfigObjConfig) (map[string]kobject.ConfigMap, error) {
+	komposeConfigs := make(map[string]kobject.ConfigMap)
+	for name, config := range configs {
+		komposeConfig := kobject.ConfigMap{
+			Name:     name,
+			File:     config.File,
+			External: config.External.External,
+		}
+
+		if !komposeConfig.External {
+			content, err := ioutil.ReadFile(config.File)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to read config %q", name)
+			}
+			komposeConfig.Content = string(content)
+		}
+
+		komposeConfigs[name] = komposeConfig
+	}
+	return komposeConfigs, nil
+}
+
+// loadV3Secrets reads the top-level `secrets:` section into kobject.Secret,
+// mirroring loadV3Configs.
+func loadV3Secrets(secrets map[string]composetypes.SecretConfig) (map[string]kobject.Secret, error) {
+	komposeSecrets := make(map[string]kobject.Secret)
+	for name, secret := range secrets {
+		komposeSecret := kobject.Secret{
+			Name:     name,
+			File:     secret.File,
+			External: secret.External.External,
+		}
+
+		if !komposeSecret.External {
+			content, err := ioutil.ReadFile(secret.File)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to read secret %q", name)
+			}
+			komposeSecret.Content = content
+		}
+
+		komposeSecrets[name] = komposeSecret
+	}
+	return komposeSecrets, nil
+}
+
+// loadV3FileReferences converts a service's `configs:`/`secrets:` mount list
+// into kobject.FileReferenceConfig, applying the Compose-spec defaults for
+// Target when the user didn't set one explicitly.
+func loadV3FileReferences(refs []composetypes.ServiceConfigObjConfig, defaultDir string) []kobject.FileReferenceConfig {
+	var fileRefs []kobject.FileReferenceConfig
+	for _, ref := range refs {
+		target := ref.Target
+		if target == "" {
+			target = defaultDir + "/" + ref.Source
+		}
+
+		fileRefs = append(fileRefs, kobject.FileReferenceConfig{
+			Source: ref.Source,
+			Target: target,
+			UID:    ref.UID,
+			GID:    ref.GID,
+			Mode:   ref.Mode,
+		})
+	}
+	return fileRefs
+}
+
+// parseNodeLabelConstraint parses a Swarm `deploy.placement.constraints`
+// entry of the form "node.labels.<key> == <value>" into its key/value pair.
+// Anything else (node.role, engine.labels, the "!=" operator, ...) reports
+// ok=false since there's no direct Kubernetes NodeSelector equivalent.
+func parseNodeLabelConstraint(constraint string) (key, value string, ok bool) {
+	parts := strings.SplitN(constraint, "==", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	lhs := strings.TrimSpace(parts[0])
+	if !strings.HasPrefix(lhs, "node.labels.") {
+		return "", "", false
+	}
+
+	return strings.TrimPrefix(lhs, "node.labels."), strings.TrimSpace(parts[1]), true
+}
+
+func dockerComposeToKomposeMapping(composeObject *composetypes.Project) (kobject.KomposeObject, error) {
 
 	// Step 1. Initialize what's going to be returned
 	komposeObject := kobject.KomposeObject{
@@ -145,6 +356,18 @@ func dockerComposeToKomposeMapping(composeObject *types.Config) (kobject.Kompose
 		LoadedFrom:     "compose",
 	}
 
+	configMaps, err := loadV3Configs(composeObject.Configs)
+	if err != nil {
+		return kobject.KomposeObject{}, err
+	}
+	komposeObject.ConfigMaps = configMaps
+
+	secrets, err := loadV3Secrets(composeObject.Secrets)
+	if err != nil {
+		return kobject.KomposeObject{}, err
+	}
+	komposeObject.Secrets = secrets
+
 	// Step 2. Parse through the object and conver it to kobject.KomposeObject!
 	// Here we "clean up" the service configuration so we return something that includes
 	// all relevant information as well as avoid the unsupported keys as well.
@@ -172,22 +395,87 @@ func dockerComposeToKomposeMapping(composeObject *types.Config) (kobject.Kompose
 
 		// This is a bit messy since we use yaml.MemStringorInt
 		// TODO: Refactor yaml.MemStringorInt in kobject.go to int64
-		// Since Deploy.Resources.Limits does not initialize, we must check type Resources before continuing
-		if (composeServiceConfig.Deploy.Resources != types.Resources{}) {
+		// composetypes.Resources embeds an Extensions map, so it isn't
+		// comparable with "!="; check Limits/Reservations directly instead.
+		if composeServiceConfig.Deploy.Resources.Limits != nil {
 			serviceConfig.MemLimit = libcomposeyaml.MemStringorInt(composeServiceConfig.Deploy.Resources.Limits.MemoryBytes)
+			serviceConfig.CPULimit = composeServiceConfig.Deploy.Resources.Limits.NanoCPUs
+		}
+
+		if reservations := composeServiceConfig.Deploy.Resources.Reservations; reservations != nil {
+			serviceConfig.MemReservation = libcomposeyaml.MemStringorInt(reservations.MemoryBytes)
+			serviceConfig.CPUReservation = reservations.NanoCPUs
+		}
+
+		// deploy.replicas overrides the transformer's default of a single
+		// replica; a pointer copy so an explicit `replicas: 0` survives
+		// distinct from "unset" all the way to the transformer.
+		if composeServiceConfig.Deploy.Replicas != nil {
+			replicas := int(*composeServiceConfig.Deploy.Replicas)
+			serviceConfig.Replicas = &replicas
+		}
+
+		// deploy.update_config.parallelism controls how many pods roll at a
+		// time; `order` decides whether those pods come up before or after
+		// the old ones go away. Compose defaults order to "stop-first" when
+		// unset, so that (not "start-first") is the default/zero-value case.
+		if updateConfig := composeServiceConfig.Deploy.UpdateConfig; updateConfig != nil && updateConfig.Parallelism != nil {
+			switch updateConfig.Order {
+			case "start-first":
+				serviceConfig.RollingUpdateMaxSurge = int(*updateConfig.Parallelism)
+			default:
+				serviceConfig.RollingUpdateMaxUnavailable = int(*updateConfig.Parallelism)
+			}
+		}
+
+		// deploy.restart_policy.condition maps onto the PodSpec-level
+		// RestartPolicy, distinct from the per-container `restart:` we
+		// already captured above in serviceConfig.Restart
+		if restartPolicy := composeServiceConfig.Deploy.RestartPolicy; restartPolicy != nil {
+			switch restartPolicy.Condition {
+			case "none":
+				serviceConfig.RestartPolicy = api.RestartPolicyNever
+			case "any":
+				serviceConfig.RestartPolicy = api.RestartPolicyAlways
+			case "on-failure":
+				serviceConfig.RestartPolicy = api.RestartPolicyOnFailure
+			}
+		}
+
+		// deploy.placement.constraints only understands the Swarm
+		// "node.labels.<key> == <value>" form; anything else (node.role,
+		// engine.labels, != operators, ...) has no Kubernetes NodeSelector
+		// equivalent and is left for a future NodeAffinity-based mapping
+		for _, constraint := range composeServiceConfig.Deploy.Placement.Constraints {
+			key, value, ok := parseNodeLabelConstraint(constraint)
+			if !ok {
+				log.Warnf("Ignoring unsupported placement constraint %q", constraint)
+				continue
+			}
+
+			if serviceConfig.Placement == nil {
+				serviceConfig.Placement = make(map[string]string)
+			}
+			serviceConfig.Placement[key] = value
 		}
 
-		// POOF. volumes_From is gone in v3. docker/cli will error out of volumes_from is added in v3
+		// POOF. volumes_From is gone in v3. compose-go will error out if volumes_from is added in v3
 		// serviceConfig.VolumesFrom = composeServiceConfig.VolumesFrom
 
-		// TODO: Build is not yet supported, see:
-		// https://github.com/docker/cli/blob/master/cli/compose/types/types.go#L9
-		// We will have to *manually* add this / parse.
-		// serviceConfig.Build = composeServiceConfig.Build.Context
-		// serviceConfig.Dockerfile = composeServiceConfig.Build.Dockerfile
+		// A service can define both `build:` and `image:`; `image:` just
+		// names the tag the build should produce. Actually invoking a
+		// builder happens afterwards, in buildServices, once every service
+		// has been mapped.
+		if composeServiceConfig.Build != nil {
+			serviceConfig.Build = composeServiceConfig.Build.Context
+			serviceConfig.Dockerfile = composeServiceConfig.Build.Dockerfile
+			serviceConfig.BuildArgs = composeServiceConfig.Build.Args
+			serviceConfig.BuildTarget = composeServiceConfig.Build.Target
+			serviceConfig.CacheFrom = composeServiceConfig.Build.CacheFrom
+		}
 
 		// Gather the environment values
-		// DockerCompose uses map[string]*string while we use []string
+		// compose-go uses map[string]*string while we use []kobject.EnvVar
 		// So let's convert that using this hack
 		for name, value := range composeServiceConfig.Environment {
 			env := kobject.EnvVar{Name: name, Value: *value}
@@ -204,6 +492,12 @@ func dockerComposeToKomposeMapping(composeObject *types.Config) (kobject.Kompose
 		// https://docs.docker.com/compose/compose-file/#long-syntax-2
 		serviceConfig.Volumes = loadV3Volumes(composeServiceConfig.Volumes)
 
+		// Parse this service's configs:/secrets: mounts. The backing
+		// ConfigMap/Secret content was already read once, up front, in
+		// loadV3Configs/loadV3Secrets.
+		serviceConfig.Configs = loadV3FileReferences(composeServiceConfig.Configs, "")
+		serviceConfig.Secrets = loadV3FileReferences(composeServiceConfig.Secrets, "/run/secrets")
+
 		// Label handler
 		// Labels used to influence conversion of kompose will be handled
 		// from here for docker-compose. Each loader will have such handler.
@@ -218,9 +512,38 @@ func dockerComposeToKomposeMapping(composeObject *types.Config) (kobject.Kompose
 				serviceConfig.ServiceType = serviceType
 			case "kompose.service.expose":
 				serviceConfig.ExposeService = strings.ToLower(value)
+			case "kompose.image-pull-secret":
+				serviceConfig.ImagePullSecrets = []string{value}
+			case "kompose.image-pull-policy":
+				pullPolicy, err := handlePullPolicy(value)
+				if err != nil {
+					return kobject.KomposeObject{}, errors.Wrap(err, "handlePullPolicy failed")
+				}
+				serviceConfig.ImagePullPolicy = pullPolicy
 			}
 		}
 
+		// x-pull-secret / x-pull-policy are the compose-spec extension field
+		// equivalents of the kompose.* labels above; a label, if present,
+		// always wins since it was set with kompose specifically in mind.
+		if secret, ok := composeServiceConfig.Extensions["x-pull-secret"].(string); ok && len(serviceConfig.ImagePullSecrets) == 0 {
+			serviceConfig.ImagePullSecrets = []string{secret}
+		}
+
+		if policy, ok := composeServiceConfig.Extensions["x-pull-policy"].(string); ok && serviceConfig.ImagePullPolicy == "" {
+			pullPolicy, err := handlePullPolicy(policy)
+			if err != nil {
+				return kobject.KomposeObject{}, errors.Wrap(err, "handlePullPolicy failed")
+			}
+			serviceConfig.ImagePullPolicy = pullPolicy
+		}
+
+		// Default the pull policy the same way Kubernetes itself does: always
+		// pull ":latest" (or untagged) images, otherwise only pull if missing.
+		if serviceConfig.ImagePullPolicy == "" {
+			serviceConfig.ImagePullPolicy = defaultImagePullPolicy(serviceConfig.Image)
+		}
+
 		// Log if the name will been changed
 		if normalizeServiceNames(name) != name {
 			log.Infof("Service name in docker-compose has been changed from %q to %q", name, normalizeServiceNames(name))
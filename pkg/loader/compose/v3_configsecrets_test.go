@@ -0,0 +1,126 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+func TestParseV3ConfigsAndSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose-v3-")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeComposeFile(t, dir, "nginx.conf", "server {}\n")
+	writeComposeFile(t, dir, "db-password.txt", "hunter2\n")
+
+	file := writeComposeFile(t, dir, "docker-compose.yml", `
+version: "3.9"
+services:
+  web:
+    image: nginx:1.17
+    configs:
+      - source: nginx-conf
+        target: /etc/nginx/nginx.conf
+    secrets:
+      - db-password
+configs:
+  nginx-conf:
+    file: ./nginx.conf
+secrets:
+  db-password:
+    file: ./db-password.txt
+`)
+
+	komposeObject, err := parseV3([]string{file}, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+
+	config, ok := komposeObject.ConfigMaps["nginx-conf"]
+	if !ok {
+		t.Fatalf("expected a 'nginx-conf' config, got %v", komposeObject.ConfigMaps)
+	}
+	if config.Content != "server {}\n" {
+		t.Errorf("expected the config's content to be read from disk, got %q", config.Content)
+	}
+
+	secret, ok := komposeObject.Secrets["db-password"]
+	if !ok {
+		t.Fatalf("expected a 'db-password' secret, got %v", komposeObject.Secrets)
+	}
+	if string(secret.Content) != "hunter2\n" {
+		t.Errorf("expected the secret's content to be read from disk, got %q", secret.Content)
+	}
+
+	web, ok := komposeObject.ServiceConfigs["web"]
+	if !ok {
+		t.Fatalf("expected a 'web' service, got %v", komposeObject.ServiceConfigs)
+	}
+
+	if len(web.Configs) != 1 || web.Configs[0].Source != "nginx-conf" || web.Configs[0].Target != "/etc/nginx/nginx.conf" {
+		t.Errorf("unexpected service configs: %+v", web.Configs)
+	}
+
+	if len(web.Secrets) != 1 || web.Secrets[0].Source != "db-password" {
+		t.Errorf("unexpected service secrets: %+v", web.Secrets)
+	}
+	if web.Secrets[0].Target != "/run/secrets/db-password" {
+		t.Errorf("expected the default secret mount path, got %q", web.Secrets[0].Target)
+	}
+}
+
+func TestParseV3ExternalConfigSkipsFileRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose-v3-")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := writeComposeFile(t, dir, "docker-compose.yml", `
+version: "3.9"
+services:
+  web:
+    image: nginx:1.17
+configs:
+  nginx-conf:
+    external: true
+`)
+
+	komposeObject, err := parseV3([]string{file}, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+
+	config, ok := komposeObject.ConfigMaps["nginx-conf"]
+	if !ok {
+		t.Fatalf("expected a 'nginx-conf' config, got %v", komposeObject.ConfigMaps)
+	}
+	if !config.External {
+		t.Errorf("expected the config to be marked external")
+	}
+	if config.Content != "" {
+		t.Errorf("expected no content to be read for an external config, got %q", config.Content)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+func writeComposeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestParseV3MultiFileOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose-v3-")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := writeComposeFile(t, dir, "docker-compose.yml", `
+version: "3.7"
+services:
+  web:
+    image: nginx:1.17
+    environment:
+      - FOO=base
+`)
+	override := writeComposeFile(t, dir, "docker-compose.override.yml", `
+version: "3.7"
+services:
+  web:
+    environment:
+      - FOO=overridden
+`)
+
+	komposeObject, err := parseV3([]string{base, override}, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+
+	web, ok := komposeObject.ServiceConfigs["web"]
+	if !ok {
+		t.Fatalf("expected a 'web' service, got %v", komposeObject.ServiceConfigs)
+	}
+
+	var found bool
+	for _, env := range web.Environment {
+		if env.Name == "FOO" {
+			found = true
+			if env.Value != "overridden" {
+				t.Errorf("expected override file to win, got FOO=%q", env.Value)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected FOO to be set, got %v", web.Environment)
+	}
+}
+
+func TestParseV3ProfileFiltering(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose-v3-")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := writeComposeFile(t, dir, "docker-compose.yml", `
+version: "3.9"
+services:
+  web:
+    image: nginx:1.17
+  debug-tools:
+    image: busybox
+    profiles: ["debug"]
+`)
+
+	withoutProfile, err := parseV3([]string{file}, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+	if _, ok := withoutProfile.ServiceConfigs["debug-tools"]; ok {
+		t.Errorf("expected debug-tools to be filtered out without --profile debug")
+	}
+
+	withProfile, err := parseV3([]string{file}, kobject.ConvertOptions{Profiles: []string{"debug"}})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+	if _, ok := withProfile.ServiceConfigs["debug-tools"]; !ok {
+		t.Errorf("expected debug-tools to be present with --profile debug")
+	}
+}
+
+// TestParseV3ValidateInterpolatesTypedFieldsBeforeSchemaCheck guards against
+// regressing to validating the raw, pre-interpolation YAML: `deploy.replicas`
+// must be a concrete int by the time the CUE schema sees it, even though the
+// file on disk spells it as the string "${REPLICAS}".
+func TestParseV3ValidateInterpolatesTypedFieldsBeforeSchemaCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose-v3-")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := writeComposeFile(t, dir, "docker-compose.yml", `
+version: "3.9"
+services:
+  web:
+    image: nginx:1.17
+    deploy:
+      replicas: ${REPLICAS}
+`)
+
+	os.Setenv("REPLICAS", "3")
+	defer os.Unsetenv("REPLICAS")
+
+	komposeObject, err := parseV3([]string{file}, kobject.ConvertOptions{Validate: true})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+
+	web, ok := komposeObject.ServiceConfigs["web"]
+	if !ok {
+		t.Fatalf("expected a 'web' service, got %v", komposeObject.ServiceConfigs)
+	}
+	if web.Replicas == nil || *web.Replicas != 3 {
+		t.Errorf("expected Replicas=3, got %v", web.Replicas)
+	}
+}
+
+// TestParseV3ValidateAgainstFullFeatureSet exercises the marshal/reparse/CUE
+// round-trip in validateProject against every shipped v3 feature that
+// actually reshapes the resolved Project -- volumes, ports, a build block,
+// and configs/secrets -- not just a single scalar field, since --validate
+// defaults to on for every user and this is the only path that would catch
+// compose-go's Services/Configs/Secrets failing to re-marshal into the shape
+// the CUE schema expects.
+func TestParseV3ValidateAgainstFullFeatureSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose-v3-")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(filepath.Join(dir, "app"), 0755); err != nil {
+		t.Fatalf("unable to create build context dir: %v", err)
+	}
+	writeComposeFile(t, dir, "nginx.conf", "server {}\n")
+	writeComposeFile(t, dir, "db-password.txt", "hunter2\n")
+
+	file := writeComposeFile(t, dir, "docker-compose.yml", `
+version: "3.9"
+services:
+  web:
+    build:
+      context: ./app
+      target: release
+    ports:
+      - "8080:80"
+    volumes:
+      - data:/var/lib/data
+    configs:
+      - source: nginx-conf
+        target: /etc/nginx/nginx.conf
+    secrets:
+      - db-password
+volumes:
+  data: {}
+configs:
+  nginx-conf:
+    file: ./nginx.conf
+secrets:
+  db-password:
+    file: ./db-password.txt
+`)
+
+	komposeObject, err := parseV3([]string{file}, kobject.ConvertOptions{Validate: true})
+	if err != nil {
+		t.Fatalf("parseV3 failed: %v", err)
+	}
+
+	web, ok := komposeObject.ServiceConfigs["web"]
+	if !ok {
+		t.Fatalf("expected a 'web' service, got %v", komposeObject.ServiceConfigs)
+	}
+	if len(web.Port) != 1 || web.Port[0].HostPort != 8080 {
+		t.Errorf("expected port 8080 to be mapped, got %+v", web.Port)
+	}
+	if len(web.Configs) != 1 || len(web.Secrets) != 1 {
+		t.Errorf("expected one config and one secret reference, got configs=%+v secrets=%+v", web.Configs, web.Secrets)
+	}
+	if _, ok := komposeObject.Secrets["db-password"]; !ok {
+		t.Errorf("expected a 'db-password' secret, got %v", komposeObject.Secrets)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func TestDefaultImagePullPolicy(t *testing.T) {
+	cases := map[string]api.PullPolicy{
+		"nginx":                  api.PullAlways,
+		"nginx:latest":           api.PullAlways,
+		"nginx:1.17":             api.PullIfNotPresent,
+		"myhost:5000/nginx":      api.PullAlways,
+		"myhost:5000/nginx:1.17": api.PullIfNotPresent,
+		"nginx@sha256:abc":       api.PullAlways,
+	}
+
+	for image, want := range cases {
+		if got := defaultImagePullPolicy(image); got != want {
+			t.Errorf("defaultImagePullPolicy(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestHandlePullPolicyRejectsUnknown(t *testing.T) {
+	if _, err := handlePullPolicy("Sometimes"); err == nil {
+		t.Errorf("expected an error for an unrecognized pull policy")
+	}
+
+	for _, valid := range []string{"Always", "IfNotPresent", "Never"} {
+		if _, err := handlePullPolicy(valid); err != nil {
+			t.Errorf("handlePullPolicy(%q) returned an unexpected error: %v", valid, err)
+		}
+	}
+}
+
+func TestImagePullSecretLabelTakesPrecedenceOverExtension(t *testing.T) {
+	project := &composetypes.Project{
+		Services: []composetypes.ServiceConfig{
+			{
+				Name:  "web",
+				Image: "nginx:1.17",
+				Labels: map[string]string{
+					"kompose.image-pull-secret": "from-label",
+				},
+				Extensions: map[string]interface{}{
+					"x-pull-secret": "from-extension",
+				},
+			},
+		},
+	}
+
+	komposeObject, err := dockerComposeToKomposeMapping(project)
+	if err != nil {
+		t.Fatalf("dockerComposeToKomposeMapping failed: %v", err)
+	}
+
+	secrets := komposeObject.ServiceConfigs["web"].ImagePullSecrets
+	if len(secrets) != 1 || secrets[0] != "from-label" {
+		t.Errorf("expected the kompose.image-pull-secret label to win, got %v", secrets)
+	}
+}
+
+func TestImagePullSecretFallsBackToExtension(t *testing.T) {
+	project := &composetypes.Project{
+		Services: []composetypes.ServiceConfig{
+			{
+				Name:  "web",
+				Image: "nginx:1.17",
+				Extensions: map[string]interface{}{
+					"x-pull-secret": "from-extension",
+				},
+			},
+		},
+	}
+
+	komposeObject, err := dockerComposeToKomposeMapping(project)
+	if err != nil {
+		t.Fatalf("dockerComposeToKomposeMapping failed: %v", err)
+	}
+
+	secrets := komposeObject.ServiceConfigs["web"].ImagePullSecrets
+	if len(secrets) != 1 || secrets[0] != "from-extension" {
+		t.Errorf("expected x-pull-secret to be used when no label is set, got %v", secrets)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func uint64Ptr(i uint64) *uint64 { return &i }
+
+func TestDockerComposeToKomposeMappingDeploy(t *testing.T) {
+	tests := []struct {
+		name   string
+		deploy composetypes.DeployConfig
+		verify func(t *testing.T, sc kobject.ServiceConfig)
+	}{
+		{
+			name: "replicas overrides default",
+			deploy: composetypes.DeployConfig{
+				Replicas: uint64Ptr(3),
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.Replicas == nil || *sc.Replicas != 3 {
+					t.Errorf("expected Replicas=3, got %v", sc.Replicas)
+				}
+			},
+		},
+		{
+			name: "explicit replicas: 0 is preserved, not treated as unset",
+			deploy: composetypes.DeployConfig{
+				Replicas: uint64Ptr(0),
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.Replicas == nil || *sc.Replicas != 0 {
+					t.Errorf("expected Replicas=0, got %v", sc.Replicas)
+				}
+			},
+		},
+		{
+			name:   "replicas unset stays nil",
+			deploy: composetypes.DeployConfig{},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.Replicas != nil {
+					t.Errorf("expected Replicas=nil, got %v", *sc.Replicas)
+				}
+			},
+		},
+		{
+			name: "update_config start-first maps to MaxSurge",
+			deploy: composetypes.DeployConfig{
+				UpdateConfig: &composetypes.UpdateConfig{
+					Parallelism: uint64Ptr(2),
+					Order:       "start-first",
+				},
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.RollingUpdateMaxSurge != 2 || sc.RollingUpdateMaxUnavailable != 0 {
+					t.Errorf("expected MaxSurge=2, got MaxSurge=%d MaxUnavailable=%d", sc.RollingUpdateMaxSurge, sc.RollingUpdateMaxUnavailable)
+				}
+			},
+		},
+		{
+			name: "update_config stop-first maps to MaxUnavailable",
+			deploy: composetypes.DeployConfig{
+				UpdateConfig: &composetypes.UpdateConfig{
+					Parallelism: uint64Ptr(1),
+					Order:       "stop-first",
+				},
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.RollingUpdateMaxUnavailable != 1 {
+					t.Errorf("expected MaxUnavailable=1, got %d", sc.RollingUpdateMaxUnavailable)
+				}
+			},
+		},
+		{
+			name: "update_config with unset order defaults to MaxUnavailable",
+			deploy: composetypes.DeployConfig{
+				UpdateConfig: &composetypes.UpdateConfig{
+					Parallelism: uint64Ptr(1),
+				},
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.RollingUpdateMaxSurge != 0 || sc.RollingUpdateMaxUnavailable != 1 {
+					t.Errorf("expected Compose's stop-first default to map to MaxUnavailable=1, got MaxSurge=%d MaxUnavailable=%d", sc.RollingUpdateMaxSurge, sc.RollingUpdateMaxUnavailable)
+				}
+			},
+		},
+		{
+			name: "restart_policy condition maps to api.RestartPolicy",
+			deploy: composetypes.DeployConfig{
+				RestartPolicy: &composetypes.RestartPolicy{Condition: "on-failure"},
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.RestartPolicy != api.RestartPolicyOnFailure {
+					t.Errorf("expected RestartPolicyOnFailure, got %v", sc.RestartPolicy)
+				}
+			},
+		},
+		{
+			name: "node label placement constraint",
+			deploy: composetypes.DeployConfig{
+				Placement: composetypes.Placement{
+					Constraints: []string{"node.labels.region == east", "node.role == worker"},
+				},
+			},
+			verify: func(t *testing.T, sc kobject.ServiceConfig) {
+				if sc.Placement["region"] != "east" {
+					t.Errorf("expected Placement[region]=east, got %v", sc.Placement)
+				}
+				if len(sc.Placement) != 1 {
+					t.Errorf("expected unsupported constraints to be skipped, got %v", sc.Placement)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &composetypes.Project{
+				Services: []composetypes.ServiceConfig{
+					{
+						Name:   "web",
+						Deploy: tt.deploy,
+					},
+				},
+			}
+
+			komposeObject, err := dockerComposeToKomposeMapping(project)
+			if err != nil {
+				t.Fatalf("dockerComposeToKomposeMapping failed: %v", err)
+			}
+
+			tt.verify(t, komposeObject.ServiceConfigs["web"])
+		})
+	}
+}
+
+func TestDockerComposeToKomposeMappingResources(t *testing.T) {
+	project := &composetypes.Project{
+		Services: []composetypes.ServiceConfig{
+			{
+				Name: "web",
+				Deploy: composetypes.DeployConfig{
+					Resources: composetypes.Resources{
+						Limits: &composetypes.Resource{
+							NanoCPUs:    "0.5",
+							MemoryBytes: 1 << 20,
+						},
+						Reservations: &composetypes.Resource{
+							NanoCPUs:    "0.1",
+							MemoryBytes: 1 << 19,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	komposeObject, err := dockerComposeToKomposeMapping(project)
+	if err != nil {
+		t.Fatalf("dockerComposeToKomposeMapping failed: %v", err)
+	}
+
+	sc := komposeObject.ServiceConfigs["web"]
+	if sc.CPULimit != "0.5" {
+		t.Errorf("expected CPULimit=\"0.5\", got %q", sc.CPULimit)
+	}
+	if sc.CPUReservation != "0.1" {
+		t.Errorf("expected CPUReservation=\"0.1\", got %q", sc.CPUReservation)
+	}
+}
+
+func TestParseNodeLabelConstraint(t *testing.T) {
+	key, value, ok := parseNodeLabelConstraint("node.labels.region == east")
+	if !ok || key != "region" || value != "east" {
+		t.Errorf("expected region/east/true, got %q/%q/%v", key, value, ok)
+	}
+
+	if _, _, ok := parseNodeLabelConstraint("node.role == manager"); ok {
+		t.Errorf("expected node.role constraint to be unsupported")
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validate checks a parsed Compose file against a CUE definition of
+// the Compose Specification, so users get every schema violation at once,
+// each with its key path. The compose loader runs it on the project
+// compose-go has already loaded, so it does not see files compose-go
+// itself fails to load.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+)
+
+// schema is a (deliberately partial) CUE definition of the Compose
+// Specification, covering the keys kompose actually maps to Kubernetes
+// objects. Unknown top-level keys are left open so newer Compose keys
+// kompose doesn't understand yet don't fail validation.
+const schema = `
+#ComposeFile: {
+	version?: string
+
+	services?: [string]: #Service
+	networks?: [string]: _
+	volumes?:  [string]: _
+	configs?:  [string]: #FileObject
+	secrets?:  [string]: #FileObject
+	...
+}
+
+#Service: {
+	image?:       string
+	command?:     string | [...string]
+	entrypoint?:  string | [...string]
+	environment?: [...string] | {[string]: string | int | null}
+	ports?: [...(string | {...})]
+	volumes?: [...(string | {...})]
+	configs?: [...(string | {...})]
+	secrets?: [...(string | {...})]
+	labels?: [string]: string
+	deploy?:  #Deploy
+	...
+}
+
+#Deploy: {
+	replicas?: int
+	resources?: {
+		limits?: {
+			cpus?:   string
+			memory?: string
+		}
+		reservations?: {
+			cpus?:   string
+			memory?: string
+		}
+	}
+	update_config?: {
+		parallelism?: int
+		order?:       "start-first" | "stop-first"
+	}
+	restart_policy?: {
+		condition?: "none" | "on-failure" | "any"
+	}
+	placement?: {
+		constraints?: [...string]
+	}
+	...
+}
+
+#FileObject: {
+	file?:     string
+	external?: bool | {name?: string}
+	...
+}
+
+#ComposeFile
+`
+
+// Validate unifies the user's parsed Compose YAML (as produced by
+// loader.ParseYAML, i.e. a plain map[string]interface{}) against schema and
+// returns a single error listing every violation found, or nil if the file
+// is valid.
+func Validate(parsed map[string]interface{}) error {
+	ctx := cuecontext.New()
+
+	schemaValue := ctx.CompileString(schema)
+	if err := schemaValue.Err(); err != nil {
+		return fmt.Errorf("invalid embedded compose schema: %w", err)
+	}
+
+	dataValue := ctx.Encode(parsed)
+	if err := dataValue.Err(); err != nil {
+		return fmt.Errorf("unable to encode compose file for validation: %w", err)
+	}
+
+	unified := schemaValue.Unify(dataValue)
+	if err := unified.Validate(cue.Concrete(true), cue.All()); err != nil {
+		return formatErrors(err)
+	}
+
+	return nil
+}
+
+// formatErrors renders every error CUE collected, each prefixed with the
+// offending key path, e.g. "services.web.deploy.replicas: " followed by
+// CUE's own description of the conflicting values.
+func formatErrors(err error) error {
+	errs := cueerrors.Errors(err)
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		path := strings.Join(e.Path(), ".")
+		if path == "" {
+			messages = append(messages, e.Error())
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", path, e.Error()))
+	}
+
+	return fmt.Errorf("compose file failed validation:\n  %s", strings.Join(messages, "\n  "))
+}
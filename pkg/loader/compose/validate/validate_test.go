@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import "testing"
+
+func TestValidateAcceptsMapEnvironment(t *testing.T) {
+	parsed := map[string]interface{}{
+		"services": map[string]interface{}{
+			"web": map[string]interface{}{
+				"image": "nginx:1.17",
+				"environment": map[string]interface{}{
+					"FOO": "bar",
+				},
+			},
+		},
+	}
+
+	if err := Validate(parsed); err != nil {
+		t.Errorf("expected a map-form environment to validate, got: %v", err)
+	}
+}
+
+func TestValidateAcceptsListEnvironment(t *testing.T) {
+	parsed := map[string]interface{}{
+		"services": map[string]interface{}{
+			"web": map[string]interface{}{
+				"image":       "nginx:1.17",
+				"environment": []interface{}{"FOO=bar"},
+			},
+		},
+	}
+
+	if err := Validate(parsed); err != nil {
+		t.Errorf("expected a list-form environment to validate, got: %v", err)
+	}
+}
+
+func TestValidateRejectsNonIntReplicas(t *testing.T) {
+	parsed := map[string]interface{}{
+		"services": map[string]interface{}{
+			"web": map[string]interface{}{
+				"image": "nginx:1.17",
+				"deploy": map[string]interface{}{
+					"replicas": "three",
+				},
+			},
+		},
+	}
+
+	if err := Validate(parsed); err == nil {
+		t.Errorf("expected a non-int replicas value to fail validation")
+	}
+}
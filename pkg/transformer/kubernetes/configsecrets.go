@@ -0,0 +1,156 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+// CreateConfigMaps turns the top-level `configs:` entries kompose parsed out
+// of the Compose file into Kubernetes ConfigMap objects, skipping any marked
+// `external: true` since those are expected to already exist in the cluster.
+func CreateConfigMaps(komposeObject kobject.KomposeObject) []*api.ConfigMap {
+	var configMaps []*api.ConfigMap
+
+	for name, config := range komposeObject.ConfigMaps {
+		if config.External {
+			continue
+		}
+
+		configMaps = append(configMaps, &api.ConfigMap{
+			ObjectMeta: api.ObjectMeta{
+				Name: name,
+			},
+			Data: map[string]string{
+				name: config.Content,
+			},
+		})
+	}
+
+	return configMaps
+}
+
+// CreateSecrets turns the top-level `secrets:` entries kompose parsed out of
+// the Compose file into Kubernetes Secret objects, skipping any marked
+// `external: true` since those are expected to already exist in the cluster.
+func CreateSecrets(komposeObject kobject.KomposeObject) []*api.Secret {
+	var secrets []*api.Secret
+
+	for name, secret := range komposeObject.Secrets {
+		if secret.External {
+			continue
+		}
+
+		secrets = append(secrets, &api.Secret{
+			ObjectMeta: api.ObjectMeta{
+				Name: name,
+			},
+			Data: map[string][]byte{
+				name: secret.Content,
+			},
+		})
+	}
+
+	return secrets
+}
+
+// fileReferenceKind tells configVolumeSource which top-level list a
+// FileReferenceConfig came from. Compose configs and secrets are separate
+// namespaces that may share a name, so this can't be inferred by looking the
+// name up in one map or the other -- the caller has to say which it meant.
+type fileReferenceKind int
+
+const (
+	configReference fileReferenceKind = iota
+	secretReference
+)
+
+// configVolumeSource projects a ConfigMap or a Secret source into the
+// container depending on kind, mirroring how the Compose spec lets
+// `configs:`/`secrets:` reference their respective top-level lists by name.
+//
+// ref.UID/ref.GID are deliberately not applied here: this vintage of
+// api.KeyToPath has no per-file ownership field, only Mode, so there is no
+// Kubernetes primitive to honor them against. addFileReferenceVolumes warns
+// when a user sets either so they aren't silently dropped.
+func configVolumeSource(ref kobject.FileReferenceConfig, kind fileReferenceKind) api.VolumeSource {
+	items := []api.KeyToPath{{
+		Key:  ref.Source,
+		Path: ref.Source,
+		Mode: intToInt32Ptr(ref.Mode),
+	}}
+
+	if kind == secretReference {
+		return api.VolumeSource{
+			Secret: &api.SecretVolumeSource{
+				SecretName: ref.Source,
+				Items:      items,
+			},
+		}
+	}
+
+	return api.VolumeSource{
+		ConfigMap: &api.ConfigMapVolumeSource{
+			LocalObjectReference: api.LocalObjectReference{Name: ref.Source},
+			Items:                items,
+		},
+	}
+}
+
+func intToInt32Ptr(mode *uint32) *int32 {
+	if mode == nil {
+		return nil
+	}
+	m := int32(*mode)
+	return &m
+}
+
+// addFileReferenceVolumes appends one Volume + VolumeMount per configs:/
+// secrets: entry on the service to the given PodSpec and container, so the
+// projected content lands at the target path the Compose file asked for.
+// kind tells it whether refs came from the service's `configs:` or
+// `secrets:` list, since that can't be recovered from refs alone.
+func addFileReferenceVolumes(podSpec *api.PodSpec, container *api.Container, refs []kobject.FileReferenceConfig, kind fileReferenceKind) {
+	for _, ref := range refs {
+		volumeName := ref.Source
+
+		if ref.UID != "" || ref.GID != "" {
+			log.Warnf("Ignoring uid/gid on %q: this Kubernetes API version has no per-file ownership field to project them onto", ref.Source)
+		}
+
+		podSpec.Volumes = append(podSpec.Volumes, api.Volume{
+			Name:         volumeName,
+			VolumeSource: configVolumeSource(ref, kind),
+		})
+
+		// ref.Target is a file path, not a directory -- mounting a
+		// ConfigMap/Secret volume there without SubPath would instead
+		// create a directory containing ref.Source as an entry, e.g.
+		// "/run/secrets/db-password/db-password". SubPath makes the
+		// projected key land directly at ref.Target.
+		container.VolumeMounts = append(container.VolumeMounts, api.VolumeMount{
+			Name:      volumeName,
+			MountPath: ref.Target,
+			SubPath:   ref.Source,
+			ReadOnly:  true,
+		})
+	}
+}
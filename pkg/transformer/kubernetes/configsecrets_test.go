@@ -0,0 +1,98 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+func TestCreateConfigMapsSkipsExternal(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ConfigMaps: map[string]kobject.ConfigMap{
+			"internal": {Name: "internal", Content: "hello"},
+			"external": {Name: "external", External: true},
+		},
+	}
+
+	configMaps := CreateConfigMaps(komposeObject)
+	if len(configMaps) != 1 {
+		t.Fatalf("expected 1 ConfigMap, got %d", len(configMaps))
+	}
+	if configMaps[0].Name != "internal" || configMaps[0].Data["internal"] != "hello" {
+		t.Errorf("unexpected ConfigMap: %+v", configMaps[0])
+	}
+}
+
+func TestCreateSecretsSkipsExternal(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		Secrets: map[string]kobject.Secret{
+			"internal": {Name: "internal", Content: []byte("shh")},
+			"external": {Name: "external", External: true},
+		},
+	}
+
+	secrets := CreateSecrets(komposeObject)
+	if len(secrets) != 1 {
+		t.Fatalf("expected 1 Secret, got %d", len(secrets))
+	}
+	if secrets[0].Name != "internal" || string(secrets[0].Data["internal"]) != "shh" {
+		t.Errorf("unexpected Secret: %+v", secrets[0])
+	}
+}
+
+func TestConfigVolumeSourceHonorsExplicitKind(t *testing.T) {
+	// Same name in both namespaces -- the kind passed by the caller, not
+	// map membership, must decide which VolumeSource comes back.
+	ref := kobject.FileReferenceConfig{Source: "shared", Target: "/etc/shared"}
+
+	configSource := configVolumeSource(ref, configReference)
+	if configSource.ConfigMap == nil || configSource.Secret != nil {
+		t.Errorf("expected a ConfigMap volume source for configReference, got %+v", configSource)
+	}
+
+	secretSource := configVolumeSource(ref, secretReference)
+	if secretSource.Secret == nil || secretSource.ConfigMap != nil {
+		t.Errorf("expected a Secret volume source for secretReference, got %+v", secretSource)
+	}
+}
+
+func TestAddFileReferenceVolumesMountsAtAFileNotADirectory(t *testing.T) {
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	addFileReferenceVolumes(podSpec, container, []kobject.FileReferenceConfig{
+		{Source: "db-password", Target: "/run/secrets/db-password"},
+	}, secretReference)
+
+	if len(container.VolumeMounts) != 1 {
+		t.Fatalf("expected 1 VolumeMount, got %d", len(container.VolumeMounts))
+	}
+
+	mount := container.VolumeMounts[0]
+	if mount.MountPath != "/run/secrets/db-password" {
+		t.Errorf("expected MountPath=/run/secrets/db-password, got %q", mount.MountPath)
+	}
+	// Without SubPath, Kubernetes mounts a directory at MountPath containing
+	// an entry named after the source -- not a regular file at MountPath.
+	if mount.SubPath != "db-password" {
+		t.Errorf("expected SubPath=db-password so the key lands directly at MountPath, got %q", mount.SubPath)
+	}
+}
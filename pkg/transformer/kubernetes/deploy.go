@@ -0,0 +1,104 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+// ApplyDeploy sets the Deployment-level replica count and rolling-update
+// strategy, and the PodSpec/Container-level resource requests/limits,
+// restart policy and node selector, from the `deploy:` values the compose
+// loader resolved onto serviceConfig.
+func ApplyDeploy(deployment *extensions.Deployment, podSpec *api.PodSpec, container *api.Container, serviceConfig kobject.ServiceConfig) {
+	if serviceConfig.Replicas != nil {
+		deployment.Spec.Replicas = int32(*serviceConfig.Replicas)
+	} else {
+		deployment.Spec.Replicas = 1
+	}
+
+	if serviceConfig.RollingUpdateMaxSurge != 0 || serviceConfig.RollingUpdateMaxUnavailable != 0 {
+		deployment.Spec.Strategy = extensions.DeploymentStrategy{
+			Type:          extensions.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &extensions.RollingUpdateDeployment{},
+		}
+
+		if serviceConfig.RollingUpdateMaxSurge != 0 {
+			deployment.Spec.Strategy.RollingUpdate.MaxSurge = intstr.FromInt(serviceConfig.RollingUpdateMaxSurge)
+		}
+		if serviceConfig.RollingUpdateMaxUnavailable != 0 {
+			deployment.Spec.Strategy.RollingUpdate.MaxUnavailable = intstr.FromInt(serviceConfig.RollingUpdateMaxUnavailable)
+		}
+	}
+
+	if serviceConfig.RestartPolicy != "" {
+		podSpec.RestartPolicy = serviceConfig.RestartPolicy
+	}
+
+	if len(serviceConfig.Placement) != 0 {
+		podSpec.NodeSelector = serviceConfig.Placement
+	}
+
+	applyResources(container, serviceConfig)
+}
+
+// applyResources translates CPULimit/CPUReservation/MemLimit/MemReservation
+// into the container's ResourceRequirements, skipping any quantity that
+// fails to parse rather than failing the whole conversion -- a malformed
+// `deploy.resources` value shouldn't block generating a manifest.
+func applyResources(container *api.Container, serviceConfig kobject.ServiceConfig) {
+	limits := api.ResourceList{}
+	requests := api.ResourceList{}
+
+	if serviceConfig.CPULimit != "" {
+		addQuantity(limits, api.ResourceCPU, serviceConfig.CPULimit)
+	}
+	if serviceConfig.MemLimit != 0 {
+		addQuantity(limits, api.ResourceMemory, strconv.FormatInt(int64(serviceConfig.MemLimit), 10))
+	}
+	if serviceConfig.CPUReservation != "" {
+		addQuantity(requests, api.ResourceCPU, serviceConfig.CPUReservation)
+	}
+	if serviceConfig.MemReservation != 0 {
+		addQuantity(requests, api.ResourceMemory, strconv.FormatInt(int64(serviceConfig.MemReservation), 10))
+	}
+
+	if len(limits) != 0 {
+		container.Resources.Limits = limits
+	}
+	if len(requests) != 0 {
+		container.Resources.Requests = requests
+	}
+}
+
+func addQuantity(list api.ResourceList, name api.ResourceName, value string) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		log.Warnf("Ignoring unparseable %s quantity %q: %v", name, value, err)
+		return
+	}
+	list[name] = quantity
+}
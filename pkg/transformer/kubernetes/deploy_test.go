@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestApplyDeployReplicasAndRestartPolicy(t *testing.T) {
+	deployment := &extensions.Deployment{}
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyDeploy(deployment, podSpec, container, kobject.ServiceConfig{
+		Replicas:      intPtr(3),
+		RestartPolicy: api.RestartPolicyOnFailure,
+		Placement:     map[string]string{"region": "east"},
+	})
+
+	if deployment.Spec.Replicas != 3 {
+		t.Errorf("expected Replicas=3, got %d", deployment.Spec.Replicas)
+	}
+	if podSpec.RestartPolicy != api.RestartPolicyOnFailure {
+		t.Errorf("expected RestartPolicyOnFailure, got %v", podSpec.RestartPolicy)
+	}
+	if podSpec.NodeSelector["region"] != "east" {
+		t.Errorf("expected NodeSelector[region]=east, got %v", podSpec.NodeSelector)
+	}
+}
+
+func TestApplyDeployDefaultsReplicasToOneWhenUnset(t *testing.T) {
+	deployment := &extensions.Deployment{}
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyDeploy(deployment, podSpec, container, kobject.ServiceConfig{})
+
+	if deployment.Spec.Replicas != 1 {
+		t.Errorf("expected Replicas=1 when deploy.replicas is unset, got %d", deployment.Spec.Replicas)
+	}
+}
+
+func TestApplyDeployHonorsExplicitReplicasZero(t *testing.T) {
+	deployment := &extensions.Deployment{}
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyDeploy(deployment, podSpec, container, kobject.ServiceConfig{
+		Replicas: intPtr(0),
+	})
+
+	if deployment.Spec.Replicas != 0 {
+		t.Errorf("expected Replicas=0 for an explicit deploy.replicas: 0, got %d", deployment.Spec.Replicas)
+	}
+}
+
+func TestApplyDeployRollingUpdateStrategy(t *testing.T) {
+	deployment := &extensions.Deployment{}
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyDeploy(deployment, podSpec, container, kobject.ServiceConfig{
+		RollingUpdateMaxSurge: 2,
+	})
+
+	if deployment.Spec.Strategy.Type != extensions.RollingUpdateDeploymentStrategyType {
+		t.Fatalf("expected a RollingUpdate strategy, got %v", deployment.Spec.Strategy.Type)
+	}
+	if deployment.Spec.Strategy.RollingUpdate.MaxSurge.IntValue() != 2 {
+		t.Errorf("expected MaxSurge=2, got %v", deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+	}
+}
+
+func TestApplyDeployResources(t *testing.T) {
+	deployment := &extensions.Deployment{}
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyDeploy(deployment, podSpec, container, kobject.ServiceConfig{
+		CPULimit:       "0.5",
+		CPUReservation: "0.1",
+	})
+
+	limit, ok := container.Resources.Limits[api.ResourceCPU]
+	if !ok || limit.String() != "500m" {
+		t.Errorf("expected CPU limit 500m, got %v (ok=%v)", limit, ok)
+	}
+
+	request, ok := container.Resources.Requests[api.ResourceCPU]
+	if !ok || request.String() != "100m" {
+		t.Errorf("expected CPU request 100m, got %v (ok=%v)", request, ok)
+	}
+}
+
+func TestApplyDeployIgnoresUnparseableQuantity(t *testing.T) {
+	deployment := &extensions.Deployment{}
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyDeploy(deployment, podSpec, container, kobject.ServiceConfig{
+		CPULimit: "not-a-number",
+	})
+
+	if _, ok := container.Resources.Limits[api.ResourceCPU]; ok {
+		t.Errorf("expected an unparseable CPU limit to be skipped, got %v", container.Resources.Limits)
+	}
+}
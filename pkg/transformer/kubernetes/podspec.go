@@ -0,0 +1,37 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+// ApplyImagePull sets the PodSpec-level ImagePullSecrets and the container's
+// ImagePullPolicy from the values the compose loader resolved onto
+// serviceConfig (x-pull-secret/x-pull-policy, or their kompose.* label
+// equivalents).
+func ApplyImagePull(podSpec *api.PodSpec, container *api.Container, serviceConfig kobject.ServiceConfig) {
+	for _, secret := range serviceConfig.ImagePullSecrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, api.LocalObjectReference{Name: secret})
+	}
+
+	if serviceConfig.ImagePullPolicy != "" {
+		container.ImagePullPolicy = serviceConfig.ImagePullPolicy
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+func TestApplyImagePull(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{
+		ImagePullSecrets: []string{"myreg"},
+		ImagePullPolicy:  api.PullAlways,
+	}
+
+	podSpec := &api.PodSpec{}
+	container := &api.Container{}
+
+	ApplyImagePull(podSpec, container, serviceConfig)
+
+	if len(podSpec.ImagePullSecrets) != 1 || podSpec.ImagePullSecrets[0].Name != "myreg" {
+		t.Errorf("expected PodSpec.ImagePullSecrets to contain %q, got %v", "myreg", podSpec.ImagePullSecrets)
+	}
+
+	if container.ImagePullPolicy != api.PullAlways {
+		t.Errorf("expected ImagePullPolicy=Always, got %v", container.ImagePullPolicy)
+	}
+}
+
+func TestApplyImagePullLeavesDefaultsAlone(t *testing.T) {
+	podSpec := &api.PodSpec{}
+	container := &api.Container{ImagePullPolicy: api.PullIfNotPresent}
+
+	ApplyImagePull(podSpec, container, kobject.ServiceConfig{})
+
+	if len(podSpec.ImagePullSecrets) != 0 {
+		t.Errorf("expected no ImagePullSecrets, got %v", podSpec.ImagePullSecrets)
+	}
+
+	if container.ImagePullPolicy != api.PullIfNotPresent {
+		t.Errorf("expected unset ImagePullPolicy on serviceConfig to leave the existing policy untouched, got %v", container.ImagePullPolicy)
+	}
+}
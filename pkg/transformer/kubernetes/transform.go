@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/kubernetes-incubator/kompose/pkg/kobject"
+)
+
+// Transform turns a parsed KomposeObject into the Kubernetes objects kompose
+// generates for it: one Deployment per service, plus any supporting
+// ConfigMaps/Secrets. Each stage of the pipeline (image pull config, deploy
+// resources, configs/secrets) contributes to the same PodSpec/Container as
+// its own request lands, rather than this function growing a new one-off
+// code path per feature.
+func Transform(komposeObject kobject.KomposeObject) ([]runtime.Object, error) {
+	var objects []runtime.Object
+
+	for name, serviceConfig := range komposeObject.ServiceConfigs {
+		container := api.Container{
+			Name:  name,
+			Image: serviceConfig.Image,
+		}
+		podSpec := api.PodSpec{
+			Containers: []api.Container{container},
+		}
+
+		ApplyImagePull(&podSpec, &podSpec.Containers[0], serviceConfig)
+		addFileReferenceVolumes(&podSpec, &podSpec.Containers[0], serviceConfig.Configs, configReference)
+		addFileReferenceVolumes(&podSpec, &podSpec.Containers[0], serviceConfig.Secrets, secretReference)
+
+		deployment := &extensions.Deployment{
+			ObjectMeta: api.ObjectMeta{Name: name},
+			Spec: extensions.DeploymentSpec{
+				Template: api.PodTemplateSpec{
+					ObjectMeta: api.ObjectMeta{Name: name},
+					Spec:       podSpec,
+				},
+			},
+		}
+
+		ApplyDeploy(deployment, &deployment.Spec.Template.Spec, &deployment.Spec.Template.Spec.Containers[0], serviceConfig)
+
+		objects = append(objects, deployment)
+	}
+
+	for _, configMap := range CreateConfigMaps(komposeObject) {
+		objects = append(objects, configMap)
+	}
+
+	for _, secret := range CreateSecrets(komposeObject) {
+		objects = append(objects, secret)
+	}
+
+	return objects, nil
+}